@@ -0,0 +1,197 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/paerser/parser"
+)
+
+// TagPaerser is the struct tag consulted for paerser-specific per-field options, currently the
+// compact delimiter syntax recognized for map[string]string and []string fields, e.g.
+// `paerser:"envSeparator=,;envKeyValSeparator=:"`. It lets a single env var such as
+// TRAEFIK_FOO_BAR=key1:v1,key2:v2 stand in for one env var per map entry, which is more practical
+// when the keys are dynamic (labels, headers, colors).
+const TagPaerser = "paerser"
+
+// compactTag holds the parsed options of a TagPaerser value.
+type compactTag struct {
+	envSeparator       string
+	envKeyValSeparator string
+}
+
+func parseCompactTag(tag string) (compactTag, bool) {
+	var ct compactTag
+	for _, opt := range strings.Split(tag, ";") {
+		k, v, ok := strings.Cut(opt, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(k) {
+		case "envSeparator":
+			ct.envSeparator = v
+		case "envKeyValSeparator":
+			ct.envKeyValSeparator = v
+		}
+	}
+
+	return ct, ct.envSeparator != ""
+}
+
+// compactPaths walks rType and returns, keyed by the dotted decode path matching the keys built
+// by Decode, the compact tag of every field that opted into the compact delimiter syntax.
+func compactPaths(rType reflect.Type, path []string) map[string]compactTag {
+	paths := make(map[string]compactTag)
+	walkCompactPaths(rType, path, paths)
+	return paths
+}
+
+func walkCompactPaths(rType reflect.Type, path []string, paths map[string]compactTag) {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if label, ok := field.Tag.Lookup(parser.TagLabel); ok && label != "" {
+			name = label
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = append(append([]string{}, path...), strings.ToLower(name))
+		}
+
+		if ct, ok := parseCompactTag(field.Tag.Get(TagPaerser)); ok {
+			paths[strings.Join(fieldPath, ".")] = ct
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			walkCompactPaths(field.Type, fieldPath, paths)
+		}
+	}
+}
+
+// expandCompactVars rewrites any compact-form entry of vars (a single value for a path that has
+// a compact tag) into the synthetic per-element keys the rest of the Decode pipeline expects.
+// The long form always wins: a path with both a compact value and nested long-form keys has its
+// compact value dropped.
+func expandCompactVars(vars map[string]string, compacts map[string]compactTag) {
+	for path, ct := range compacts {
+		raw, ok := vars[path]
+		if !ok {
+			continue
+		}
+
+		longFormPrefix := path + "."
+		hasLongForm := false
+		for k := range vars {
+			if k != path && strings.HasPrefix(k, longFormPrefix) {
+				hasLongForm = true
+				break
+			}
+		}
+
+		delete(vars, path)
+		if hasLongForm || raw == "" {
+			continue
+		}
+
+		var index int
+		for _, entry := range strings.Split(raw, ct.envSeparator) {
+			if entry == "" {
+				continue
+			}
+
+			if ct.envKeyValSeparator != "" {
+				if k, v, ok := strings.Cut(entry, ct.envKeyValSeparator); ok {
+					vars[longFormPrefix+k] = v
+					continue
+				}
+			}
+
+			vars[longFormPrefix+strconv.Itoa(index)] = entry
+			index++
+		}
+	}
+}
+
+// compactFlats merges the flat entries produced for a compact-tagged field back into a single
+// entry holding the compact form, mirroring what Decode accepts.
+func compactFlats(flats []parser.Flat, compacts map[string]compactTag) []parser.Flat {
+	if len(compacts) == 0 {
+		return flats
+	}
+
+	byPath := make(map[string][]parser.Flat)
+	tags := make(map[string]compactTag)
+	var order []string
+	result := make([]parser.Flat, 0, len(flats))
+
+	for _, flat := range flats {
+		path, ct, ok := matchCompactPath(flat.Name, compacts)
+		if !ok {
+			result = append(result, flat)
+			continue
+		}
+
+		if _, seen := byPath[path]; !seen {
+			order = append(order, path)
+			tags[path] = ct
+		}
+		byPath[path] = append(byPath[path], flat)
+	}
+
+	for _, path := range order {
+		group := byPath[path]
+		ct := tags[path]
+
+		entries := make([]string, 0, len(group))
+		for _, flat := range group {
+			key := strings.TrimPrefix(flat.Name, path+"_")
+			if ct.envKeyValSeparator != "" {
+				entries = append(entries, key+ct.envKeyValSeparator+flat.Default)
+			} else {
+				entries = append(entries, flat.Default)
+			}
+		}
+
+		merged := group[0]
+		merged.Name = path
+		merged.Default = strings.Join(entries, ct.envSeparator)
+		result = append(result, merged)
+	}
+
+	return result
+}
+
+// matchCompactPath reports whether name (an upper-cased, underscore-separated env var name)
+// falls under one of the dotted compact paths, returning the matched path rewritten in name's
+// own case/separator convention.
+func matchCompactPath(name string, compacts map[string]compactTag) (string, compactTag, bool) {
+	for path, ct := range compacts {
+		candidate := strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		if strings.HasPrefix(name, candidate+"_") {
+			return candidate, ct, true
+		}
+	}
+
+	return "", compactTag{}, false
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/paerser/parser"
+)
+
+type compactConfig struct {
+	Headers map[string]string `paerser:"envSeparator=,;envKeyValSeparator=:"`
+	Users   []string          `paerser:"envSeparator=,"`
+}
+
+// TestDecode_CompactForm proves, end-to-end through the public Decode entry point, that a single
+// env var in the compact delimiter form populates a map[string]string or []string field just as
+// the regular one-var-per-leaf form would.
+func TestDecode_CompactForm(t *testing.T) {
+	var cfg compactConfig
+
+	environ := []string{
+		"TRAEFIK_HEADERS=X-Foo:bar,X-Baz:qux",
+		"TRAEFIK_USERS=rob,ken,robert",
+	}
+
+	if err := Decode(environ, []string{DefaultNamePrefix}, &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantHeaders := map[string]string{"X-Foo": "bar", "X-Baz": "qux"}
+	if !reflect.DeepEqual(cfg.Headers, wantHeaders) {
+		t.Errorf("got Headers %#v, want %#v", cfg.Headers, wantHeaders)
+	}
+
+	wantUsers := []string{"rob", "ken", "robert"}
+	if !reflect.DeepEqual(cfg.Users, wantUsers) {
+		t.Errorf("got Users %#v, want %#v", cfg.Users, wantUsers)
+	}
+}
+
+func TestExpandCompactVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		compacts map[string]compactTag
+		want     map[string]string
+	}{
+		{
+			name: "compact map form",
+			vars: map[string]string{"traefik.foo.bar": "key1:v1,key2:v2"},
+			compacts: map[string]compactTag{
+				"traefik.foo.bar": {envSeparator: ",", envKeyValSeparator: ":"},
+			},
+			want: map[string]string{
+				"traefik.foo.bar.key1": "v1",
+				"traefik.foo.bar.key2": "v2",
+			},
+		},
+		{
+			name: "compact slice form",
+			vars: map[string]string{"traefik.users": "rob,ken,robert"},
+			compacts: map[string]compactTag{
+				"traefik.users": {envSeparator: ","},
+			},
+			want: map[string]string{
+				"traefik.users.0": "rob",
+				"traefik.users.1": "ken",
+				"traefik.users.2": "robert",
+			},
+		},
+		{
+			name: "long form wins over compact value",
+			vars: map[string]string{
+				"traefik.foo.bar":      "key1:v1",
+				"traefik.foo.bar.key2": "v2",
+			},
+			compacts: map[string]compactTag{
+				"traefik.foo.bar": {envSeparator: ",", envKeyValSeparator: ":"},
+			},
+			want: map[string]string{
+				"traefik.foo.bar.key2": "v2",
+			},
+		},
+		{
+			name:     "no compact tag leaves vars untouched",
+			vars:     map[string]string{"traefik.foo": "bar"},
+			compacts: map[string]compactTag{},
+			want:     map[string]string{"traefik.foo": "bar"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vars := make(map[string]string, len(test.vars))
+			for k, v := range test.vars {
+				vars[k] = v
+			}
+
+			expandCompactVars(vars, test.compacts)
+
+			if !reflect.DeepEqual(vars, test.want) {
+				t.Fatalf("got %v, want %v", vars, test.want)
+			}
+		})
+	}
+}
+
+func TestCompactFlats(t *testing.T) {
+	flats := []parser.Flat{
+		{Name: "TRAEFIK_FOO_BAR_KEY1", Default: "v1"},
+		{Name: "TRAEFIK_FOO_BAR_KEY2", Default: "v2"},
+		{Name: "TRAEFIK_OTHER", Default: "x"},
+	}
+	compacts := map[string]compactTag{
+		"traefik.foo.bar": {envSeparator: ",", envKeyValSeparator: ":"},
+	}
+
+	want := []parser.Flat{
+		{Name: "TRAEFIK_OTHER", Default: "x"},
+		{Name: "TRAEFIK_FOO_BAR", Default: "KEY1:v1,KEY2:v2"},
+	}
+
+	got := compactFlats(flats, compacts)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestCompactFlats_NoCompacts(t *testing.T) {
+	flats := []parser.Flat{{Name: "TRAEFIK_FOO", Default: "x"}}
+
+	got := compactFlats(flats, nil)
+
+	if !reflect.DeepEqual(got, flats) {
+		t.Fatalf("got %#v, want %#v", got, flats)
+	}
+}
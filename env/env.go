@@ -2,7 +2,9 @@
 package env
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -12,31 +14,85 @@ import (
 // DefaultNamePrefix is the default prefix for environment variable names.
 const DefaultNamePrefix = "TRAEFIK_"
 
+// TagEnvPrefix is the struct tag used to override the env var prefix of a nested struct field.
+// Rather than being addressed below its parent's structural path, a field tagged this way is
+// addressed directly below the root, under its own tag value. This lets the same struct type be
+// embedded more than once while each instance keeps a short, unambiguous namespace, e.g.
+// `envPrefix:"FOO_HOME_"` and `envPrefix:"BAR_HOME_"` on two fields of the same type.
+const TagEnvPrefix = "envPrefix"
+
 // Decode decodes the given environment variables into the given element.
+// At least one prefix must be given. Every environment variable is matched
+// against each prefix in turn, so several namespaces (e.g. a legacy prefix
+// kept for backward compatibility) can feed the same element. Nested struct
+// fields tagged with TagEnvPrefix are addressed below their own namespace,
+// regardless of which root prefix matched. A map[string]string or []string field tagged with
+// TagPaerser's compact delimiter syntax also accepts a single env var holding every entry
+// (e.g. FOO_BAR=key1:v1,key2:v2), in addition to the regular one-var-per-leaf form; the regular
+// form wins if both are present. A field whose type was taught to the package via Register is
+// decoded with that type's parse function instead of the built-in kind-based handling.
 // The operation goes through four stages roughly summarized as:
 // - env vars -> map
 // - map -> tree of untyped nodes
 // - untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
 // - "typed" nodes -> typed element.
-func Decode(environ []string, prefix string, element interface{}) error {
-	if err := checkPrefix(prefix); err != nil {
-		return err
+func Decode(environ []string, prefixes []string, element interface{}) error {
+	if len(prefixes) == 0 {
+		return errors.New("at least one prefix is required")
+	}
+
+	for _, prefix := range prefixes {
+		if err := checkPrefix(prefix); err != nil {
+			return err
+		}
 	}
 
+	rootName := strings.ToLower(prefixes[0][:len(prefixes[0])-1])
+
+	rewrites := envPrefixRewrites(reflect.TypeOf(element))
+	regPaths := registeredPaths(reflect.TypeOf(element), []string{rootName})
+
 	vars := make(map[string]string)
 	for _, evr := range environ {
 		k, v, _ := strings.Cut(evr, "=")
-		if strings.HasPrefix(strings.ToUpper(k), prefix) {
-			key := strings.ReplaceAll(strings.ToLower(k), "_", ".")
-			vars[key] = v
+		upperKey := strings.ToUpper(k)
+
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(upperKey, prefix) {
+				continue
+			}
+
+			remainder := applyEnvPrefixRewrites(k[len(prefix):], rewrites)
+
+			key := rootName
+			if suffix := strings.ReplaceAll(strings.ToLower(remainder), "_", "."); suffix != "" {
+				key += "." + suffix
+			}
+
+			resolved, err := resolveValue(v)
+			if err != nil {
+				return fmt.Errorf("resolving %s: %w", k, err)
+			}
+			vars[key] = resolved
+			break
 		}
 	}
 
-	rootName := strings.ToLower(prefix[:len(prefix)-1])
-	return parser.Decode(vars, element, rootName)
+	registeredRaw := popRegisteredVars(vars, regPaths)
+
+	expandCompactVars(vars, compactPaths(reflect.TypeOf(element), []string{rootName}))
+
+	if err := parser.Decode(vars, element, rootName); err != nil {
+		return err
+	}
+
+	return applyRegisteredVars(element, regPaths, registeredRaw)
 }
 
-// Encode encodes the configuration in element into the environment variables represented in the returned Flats.
+// Encode encodes the configuration in element into the environment variables represented in the
+// returned Flats. Fields tagged with TagPaerser's compact delimiter syntax are encoded as a
+// single compact-form Flat rather than one Flat per entry. A field whose type was taught to the
+// package via Register has its Default produced by that type's encode function.
 // The operation goes through three stages roughly summarized as:
 // - typed configuration in element -> tree of untyped nodes
 // - untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
@@ -65,7 +121,81 @@ func Encode(prefix string, element interface{}) ([]parser.Flat, error) {
 	}
 
 	flatOpts := parser.FlatOpts{Case: "upper", Separator: "_", TagName: parser.TagLabel}
-	return parser.EncodeToFlat(element, node, flatOpts)
+	flats, err := parser.EncodeToFlat(element, node, flatOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	rType := reflect.TypeOf(element)
+	flats = overrideRegisteredDefaults(flats, element, registeredPaths(rType, []string{rootName}))
+	flats = compactFlats(flats, compactPaths(rType, []string{rootName}))
+	flats = rewriteEnvPrefixNames(flats, rootName, envPrefixRewrites(rType))
+
+	return flats, nil
+}
+
+// FindPrefixedEnvVars returns the entries of environ whose key is one of the concrete top-level
+// env prefixes derived from element (e.g. "TRAEFIK_HTTP", "TRAEFIK_LOG"), or starts with one of
+// them followed by an underscore (see hasPrefixBoundary). It lets
+// callers narrow a noisy environment before calling Decode, or detect unknown/misspelled
+// variables by diffing environ against the returned list.
+func FindPrefixedEnvVars(environ []string, prefix string, element interface{}) []string {
+	if element == nil {
+		return nil
+	}
+
+	rootPrefixes := findRootPrefixes(reflect.TypeOf(element), strings.ToUpper(prefix))
+
+	var result []string
+	for _, evr := range environ {
+		k, _, _ := strings.Cut(evr, "=")
+		upperKey := strings.ToUpper(k)
+
+		for _, rootPrefix := range rootPrefixes {
+			if hasPrefixBoundary(upperKey, rootPrefix) {
+				result = append(result, evr)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// hasPrefixBoundary reports whether key is rootPrefix itself, or starts with rootPrefix followed
+// by an underscore, so a field named Log (prefix TRAEFIK_LOG) doesn't also match an unrelated
+// TRAEFIK_LOGFORMAT or a misspelled TRAEFIK_LOGFORMATX_TYPO.
+func hasPrefixBoundary(key, rootPrefix string) bool {
+	return key == rootPrefix || strings.HasPrefix(key, rootPrefix+"_")
+}
+
+// findRootPrefixes reflects on the exported and anonymous embedded fields of the struct behind
+// rType to compute the set of concrete top-level env var prefixes rooted at prefix.
+func findRootPrefixes(rType reflect.Type, prefix string) []string {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var prefixes []string
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous {
+			prefixes = append(prefixes, findRootPrefixes(field.Type, prefix)...)
+			continue
+		}
+
+		prefixes = append(prefixes, prefix+strings.ToUpper(field.Name))
+	}
+
+	return prefixes
 }
 
 func checkPrefix(prefix string) error {
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type findPrefixedConfig struct {
+	HTTP struct{}
+	Log  struct{}
+}
+
+func TestFindPrefixedEnvVars(t *testing.T) {
+	environ := []string{
+		"TRAEFIK_HTTP_ROUTERS_FOO_RULE=Host(`example.com`)",
+		"TRAEFIK_LOG_LEVEL=DEBUG",
+		"TRAEFIK_UNKNOWN_FIELD=typo",
+		"UNRELATED=1",
+	}
+
+	got := FindPrefixedEnvVars(environ, DefaultNamePrefix, findPrefixedConfig{})
+
+	want := []string{
+		"TRAEFIK_HTTP_ROUTERS_FOO_RULE=Host(`example.com`)",
+		"TRAEFIK_LOG_LEVEL=DEBUG",
+	}
+
+	sort.Strings(got)
+	sort.Strings(want)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// TestFindPrefixedEnvVars_PrefixBoundary proves that a root field (Log, prefix TRAEFIK_LOG)
+// doesn't also match an unrelated var that merely shares that prefix as a substring, such as a
+// genuine TRAEFIK_LOGFORMAT field or a misspelled TRAEFIK_LOGFORMATX_TYPO.
+func TestFindPrefixedEnvVars_PrefixBoundary(t *testing.T) {
+	type config struct {
+		Log       struct{}
+		LogFormat struct{}
+	}
+
+	environ := []string{
+		"TRAEFIK_LOG_LEVEL=DEBUG",
+		"TRAEFIK_LOGFORMAT_STYLE=json",
+		"TRAEFIK_LOGFORMATX_TYPO=oops",
+	}
+
+	got := FindPrefixedEnvVars(environ, DefaultNamePrefix, config{})
+
+	want := []string{
+		"TRAEFIK_LOG_LEVEL=DEBUG",
+		"TRAEFIK_LOGFORMAT_STYLE=json",
+	}
+
+	sort.Strings(got)
+	sort.Strings(want)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindPrefixedEnvVars_NilElement(t *testing.T) {
+	got := FindPrefixedEnvVars([]string{"TRAEFIK_FOO=bar"}, DefaultNamePrefix, nil)
+
+	if got != nil {
+		t.Fatalf("got %v, want nil", got)
+	}
+}
@@ -0,0 +1,121 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/traefik/paerser/parser"
+)
+
+// envPrefixRewrite pairs a field's TagEnvPrefix tag token (the tag value, upper-cased, with any
+// trailing underscore trimmed) with the dotted structural path Decode/Encode would otherwise use
+// to reach that field. This lets two fields sharing the same struct type each get their own short,
+// flat namespace instead of being addressed through their structural position in the tree.
+type envPrefixRewrite struct {
+	token string // e.g. "FOO_HOME"
+	path  string // e.g. "section.home"
+}
+
+// envPrefixRewrites walks rType and collects the TagEnvPrefix tag of every field, alongside its
+// structural path (built the same way Decode/Encode derive names from field names: the label tag
+// if present, the Go field name otherwise).
+func envPrefixRewrites(rType reflect.Type) []envPrefixRewrite {
+	var rewrites []envPrefixRewrite
+	walkEnvPrefixRewrites(rType, nil, &rewrites)
+	return rewrites
+}
+
+func walkEnvPrefixRewrites(rType reflect.Type, path []string, rewrites *[]envPrefixRewrite) {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if label, ok := field.Tag.Lookup(parser.TagLabel); ok && label != "" {
+			name = label
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = append(append([]string{}, path...), strings.ToLower(name))
+		}
+
+		if tag, ok := field.Tag.Lookup(TagEnvPrefix); ok && tag != "" {
+			*rewrites = append(*rewrites, envPrefixRewrite{
+				token: strings.ToUpper(strings.TrimSuffix(tag, "_")),
+				path:  strings.Join(fieldPath, "."),
+			})
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			walkEnvPrefixRewrites(field.Type, fieldPath, rewrites)
+		}
+	}
+}
+
+// applyEnvPrefixRewrites rewrites the remainder of an env var key (upper-cased, underscore
+// separated, with the matched root prefix already stripped) so that a field's envPrefix token is
+// replaced by its structural path, letting the rest of Decode route the value as if the field had
+// no envPrefix tag at all.
+func applyEnvPrefixRewrites(remainder string, rewrites []envPrefixRewrite) string {
+	upper := strings.ToUpper(remainder)
+	structuralPath := func(rw envPrefixRewrite) string {
+		return strings.ToUpper(strings.ReplaceAll(rw.path, ".", "_"))
+	}
+
+	for _, rw := range rewrites {
+		if upper == rw.token {
+			return structuralPath(rw)
+		}
+		if strings.HasPrefix(upper, rw.token+"_") {
+			return structuralPath(rw) + "_" + remainder[len(rw.token)+1:]
+		}
+	}
+
+	return remainder
+}
+
+// rewriteEnvPrefixNames rewrites the flat names produced for envPrefix-tagged fields from their
+// structural path back to the field's own envPrefix token, mirroring what Decode accepts.
+func rewriteEnvPrefixNames(flats []parser.Flat, rootName string, rewrites []envPrefixRewrite) []parser.Flat {
+	if len(rewrites) == 0 {
+		return flats
+	}
+
+	prefix := strings.ToUpper(rootName) + "_"
+
+	result := make([]parser.Flat, len(flats))
+	for i, flat := range flats {
+		result[i] = flat
+
+		for _, rw := range rewrites {
+			structural := prefix + strings.ToUpper(strings.ReplaceAll(rw.path, ".", "_"))
+			switch {
+			case flat.Name == structural:
+				result[i].Name = prefix + rw.token
+			case strings.HasPrefix(flat.Name, structural+"_"):
+				result[i].Name = prefix + rw.token + "_" + strings.TrimPrefix(flat.Name, structural+"_")
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	return result
+}
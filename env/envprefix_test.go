@@ -0,0 +1,144 @@
+package env
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/traefik/paerser/parser"
+)
+
+type envPrefixHome struct {
+	Path string
+}
+
+type envPrefixConfig struct {
+	Foo envPrefixHome `envPrefix:"FOO_HOME_"`
+	Bar envPrefixHome `envPrefix:"BAR_HOME_"`
+}
+
+func TestEnvPrefixRewrites(t *testing.T) {
+	rewrites := envPrefixRewrites(reflect.TypeOf(envPrefixConfig{}))
+
+	sort.Slice(rewrites, func(i, j int) bool { return rewrites[i].token < rewrites[j].token })
+
+	want := []envPrefixRewrite{
+		{token: "BAR_HOME", path: "bar"},
+		{token: "FOO_HOME", path: "foo"},
+	}
+
+	if !reflect.DeepEqual(rewrites, want) {
+		t.Fatalf("got %#v, want %#v", rewrites, want)
+	}
+}
+
+// TestApplyEnvPrefixRewrites proves that two fields of the same struct type, each tagged with a
+// distinct envPrefix, are routed to their own structural path independently of one another.
+func TestApplyEnvPrefixRewrites(t *testing.T) {
+	rewrites := envPrefixRewrites(reflect.TypeOf(envPrefixConfig{}))
+
+	tests := []struct {
+		name      string
+		remainder string
+		want      string
+	}{
+		{name: "first instance", remainder: "FOO_HOME_PATH", want: "FOO_PATH"},
+		{name: "second instance", remainder: "BAR_HOME_PATH", want: "BAR_PATH"},
+		{name: "exact token match", remainder: "FOO_HOME", want: "FOO"},
+		{name: "unrelated key is untouched", remainder: "OTHER_KEY", want: "OTHER_KEY"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := applyEnvPrefixRewrites(test.remainder, rewrites)
+			if got != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRewriteEnvPrefixNames(t *testing.T) {
+	rewrites := envPrefixRewrites(reflect.TypeOf(envPrefixConfig{}))
+
+	flats := []parser.Flat{
+		{Name: "TRAEFIK_FOO_PATH", Default: "a"},
+		{Name: "TRAEFIK_BAR_PATH", Default: "b"},
+		{Name: "TRAEFIK_OTHER", Default: "c"},
+	}
+
+	want := []parser.Flat{
+		{Name: "TRAEFIK_FOO_HOME_PATH", Default: "a"},
+		{Name: "TRAEFIK_BAR_HOME_PATH", Default: "b"},
+		{Name: "TRAEFIK_OTHER", Default: "c"},
+	}
+
+	got := rewriteEnvPrefixNames(flats, "traefik", rewrites)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestRewriteEnvPrefixNames_NoRewrites(t *testing.T) {
+	flats := []parser.Flat{{Name: "TRAEFIK_FOO", Default: "a"}}
+
+	got := rewriteEnvPrefixNames(flats, "traefik", nil)
+
+	if !reflect.DeepEqual(got, flats) {
+		t.Fatalf("got %#v, want %#v", got, flats)
+	}
+}
+
+// TestDecode_EnvPrefixRouting proves, end-to-end through the public Decode entry point, that two
+// fields of the same struct type decode independently when each is tagged with its own envPrefix.
+func TestDecode_EnvPrefixRouting(t *testing.T) {
+	var cfg envPrefixConfig
+
+	environ := []string{
+		"TRAEFIK_FOO_HOME_PATH=/srv/foo",
+		"TRAEFIK_BAR_HOME_PATH=/srv/bar",
+	}
+
+	if err := Decode(environ, []string{DefaultNamePrefix}, &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Foo.Path != "/srv/foo" {
+		t.Errorf("got Foo.Path %q, want %q", cfg.Foo.Path, "/srv/foo")
+	}
+	if cfg.Bar.Path != "/srv/bar" {
+		t.Errorf("got Bar.Path %q, want %q", cfg.Bar.Path, "/srv/bar")
+	}
+}
+
+// TestDecode_MultiplePrefixes proves that a value present under a secondary prefix is picked up
+// just as if it had been given under the primary one.
+func TestDecode_MultiplePrefixes(t *testing.T) {
+	var cfg envPrefixConfig
+
+	environ := []string{"LEGACY_FOO_HOME_PATH=/srv/legacy"}
+
+	err := Decode(environ, []string{DefaultNamePrefix, "LEGACY_"}, &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Foo.Path != "/srv/legacy" {
+		t.Fatalf("got Foo.Path %q, want %q", cfg.Foo.Path, "/srv/legacy")
+	}
+}
+
+func TestDecode_NoPrefixes(t *testing.T) {
+	err := Decode(nil, nil, &envPrefixConfig{})
+	if err == nil {
+		t.Fatal("expected an error when no prefix is given")
+	}
+}
+
+func TestDecode_InvalidPrefix(t *testing.T) {
+	err := Decode(nil, []string{"not-a-prefix"}, &envPrefixConfig{})
+	if err == nil {
+		t.Fatal("expected an error for an invalid prefix")
+	}
+}
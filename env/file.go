@@ -0,0 +1,185 @@
+package env
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DecodeFile reads the dotenv-style file at path and decodes it into element, as Decode would.
+// It supports "#" comments, blank lines, quoted values with escape sequences, and an optional
+// "export " prefix on each line, so existing .env files used by other tools can be reused as-is.
+func DecodeFile(path string, prefix string, element interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return DecodeReader(f, prefix, element)
+}
+
+// DecodeReader reads a dotenv-style stream from r and decodes it into element, as DecodeFile would.
+func DecodeReader(r io.Reader, prefix string, element interface{}) error {
+	environ, err := parseDotEnv(r)
+	if err != nil {
+		return err
+	}
+
+	return Decode(environ, []string{prefix}, element)
+}
+
+// parseDotEnv parses a dotenv-style stream into a []string of KEY=VALUE entries suitable for Decode.
+func parseDotEnv(r io.Reader) ([]string, error) {
+	var environ []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		unquoted, err := unquoteDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("parsing value for %s: %w", key, err)
+		}
+
+		environ = append(environ, strings.TrimSpace(key)+"="+unquoted)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return environ, nil
+}
+
+// unquoteDotEnvValue strips a surrounding pair of quotes from a dotenv value and, for
+// double-quoted values, interprets backslash escape sequences.
+func unquoteDotEnvValue(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+
+	switch value[0] {
+	case '\'':
+		if value[len(value)-1] == '\'' {
+			return value[1 : len(value)-1], nil
+		}
+		return "", fmt.Errorf("unterminated quoted value: %s", value)
+	case '"':
+		if value[len(value)-1] == '"' {
+			return strconv.Unquote(value)
+		}
+		return "", fmt.Errorf("unterminated quoted value: %s", value)
+	}
+
+	return value, nil
+}
+
+// Merge layers multiple []string sources of environment variables into one, in increasing order
+// of precedence: entries from a later source overwrite entries of the same key from an earlier
+// one. This is typically used to layer a file, the process environ, and an explicit override map
+// (converted to KEY=VALUE pairs) in that order.
+func Merge(sources ...[]string) []string {
+	values := make(map[string]string)
+	var order []string
+
+	for _, source := range sources {
+		for _, evr := range source {
+			k, v, _ := strings.Cut(evr, "=")
+			if _, ok := values[k]; !ok {
+				order = append(order, k)
+			}
+			values[k] = v
+		}
+	}
+
+	merged := make([]string, 0, len(order))
+	for _, k := range order {
+		merged = append(merged, k+"="+values[k])
+	}
+
+	return merged
+}
+
+// Watch watches the file at path for changes and, on every write, decodes it into a fresh value
+// of element's type under prefix and passes it to cb, which also receives any decode error (in
+// which case the fresh value is nil and the last known-good configuration is left in effect by
+// the caller). Watch itself returns an error, without calling cb, if the initial decode or the
+// watch setup fails; once that succeeds, it returns nil and the watch runs in the background
+// until ctx is canceled. The parent directory, rather than the file itself, is watched so that
+// reloads keep working across the write-to-temp-then-rename pattern many editors and
+// config-reload tools use, which would otherwise orphan a watch on the file's original inode.
+func Watch(ctx context.Context, path string, prefix string, element interface{}, cb func(interface{}, error)) error {
+	decode := func() (interface{}, error) {
+		next := reflect.New(reflect.TypeOf(element).Elem()).Interface()
+		if err := DecodeFile(path, prefix, next); err != nil {
+			return nil, err
+		}
+		return next, nil
+	}
+
+	initial, err := decode()
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	cb(initial, nil)
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					next, err := decode()
+					cb(next, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				cb(nil, err)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fileConfig struct {
+	Level string
+}
+
+// TestDecodeFile proves, end-to-end through the public DecodeFile entry point, that a dotenv-style
+// file on disk decodes into the target struct just as an equivalent []string environ would.
+func TestDecodeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	if err := os.WriteFile(path, []byte("# a comment\nexport TRAEFIK_LEVEL=DEBUG\n"), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	var cfg fileConfig
+	if err := DecodeFile(path, DefaultNamePrefix, &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Level != "DEBUG" {
+		t.Fatalf("got Level %q, want %q", cfg.Level, "DEBUG")
+	}
+}
+
+// TestWatch proves, end-to-end through the public Watch entry point, that it decodes the file
+// once up front and again every time the file changes, under the given prefix.
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	if err := os.WriteFile(path, []byte("TRAEFIK_LEVEL=INFO\n"), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan *fileConfig, 2)
+	err := Watch(ctx, path, DefaultNamePrefix, &fileConfig{}, func(v interface{}, err error) {
+		if err != nil {
+			t.Errorf("unexpected callback error: %v", err)
+			return
+		}
+		updates <- v.(*fileConfig)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case cfg := <-updates:
+		if cfg.Level != "INFO" {
+			t.Fatalf("got initial Level %q, want %q", cfg.Level, "INFO")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the initial callback")
+	}
+
+	if err := os.WriteFile(path, []byte("TRAEFIK_LEVEL=DEBUG\n"), 0o600); err != nil {
+		t.Fatalf("rewriting fixture: %v", err)
+	}
+
+	select {
+	case cfg := <-updates:
+		if cfg.Level != "DEBUG" {
+			t.Fatalf("got reloaded Level %q, want %q", cfg.Level, "DEBUG")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload callback")
+	}
+}
+
+func TestUnquoteDotEnvValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unquoted", value: "plain", want: "plain"},
+		{name: "single quoted", value: "'hello world'", want: "hello world"},
+		{name: "double quoted", value: `"hello world"`, want: "hello world"},
+		{name: "double quoted with escape", value: `"line1\nline2"`, want: "line1\nline2"},
+		{name: "empty", value: "", want: ""},
+		{name: "unterminated single quote", value: "'hello", wantErr: true},
+		{name: "unterminated double quote", value: `"hello`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := unquoteDotEnvValue(test.value)
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none (value %q)", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	input := `
+# a comment
+TRAEFIK_FOO=bar
+
+export TRAEFIK_BAR=baz
+TRAEFIK_QUOTED="hello world"
+`
+
+	got, err := parseDotEnv(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"TRAEFIK_FOO=bar",
+		"TRAEFIK_BAR=baz",
+		"TRAEFIK_QUOTED=hello world",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entry %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseDotEnv_InvalidQuoting(t *testing.T) {
+	_, err := parseDotEnv(strings.NewReader(`TRAEFIK_FOO="unterminated`))
+	if err == nil {
+		t.Fatal("expected an error for an unterminated quoted value")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	file := []string{"TRAEFIK_FOO=file", "TRAEFIK_BAR=file"}
+	environ := []string{"TRAEFIK_FOO=environ"}
+	explicit := []string{"TRAEFIK_BAR=explicit"}
+
+	got := Merge(file, environ, explicit)
+
+	want := map[string]string{
+		"TRAEFIK_FOO": "environ",
+		"TRAEFIK_BAR": "explicit",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want entries matching %v", got, want)
+	}
+
+	for _, evr := range got {
+		k, v, _ := strings.Cut(evr, "=")
+		if want[k] != v {
+			t.Fatalf("key %s: got %q, want %q", k, v, want[k])
+		}
+	}
+}
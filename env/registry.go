@@ -0,0 +1,236 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/traefik/paerser/parser"
+)
+
+// filePrefix marks an env var value as a reference to a file whose contents should be used
+// instead, e.g. TRAEFIK_CERTIFICATESRESOLVERS_FOO_ACME_EMAIL=file:///run/secrets/email. This is
+// useful for Docker/Kubernetes secrets mounted as files.
+const filePrefix = "file://"
+
+// registeredType holds the parse/encode pair a single call to Register taught the package about T,
+// adapted to the untyped form the rest of the package deals in.
+type registeredType struct {
+	parse  func(string) (interface{}, error)
+	encode func(interface{}) string
+}
+
+// registry holds every type taught to Decode/Encode via Register, keyed by its reflect.Type.
+var registry = map[reflect.Type]registeredType{}
+
+// Register teaches Decode and Encode how to handle values of type T without requiring T to
+// implement the parser package's setter interfaces, which is useful for types the caller doesn't
+// own: net.IP, time.Location, regexp.Regexp, custom enums, and so on. Once registered, every field
+// of type T decodes and encodes through parse/encode instead of the built-in kind-based handling.
+// Register is meant to be called from init functions, before any Decode/Encode call observes T.
+func Register[T any](parse func(string) (T, error), encode func(T) string) {
+	var zero T
+
+	registry[reflect.TypeOf(&zero).Elem()] = registeredType{
+		parse: func(value string) (interface{}, error) {
+			return parse(value)
+		},
+		encode: func(value interface{}) string {
+			return encode(value.(T))
+		},
+	}
+}
+
+// registeredPaths walks rType and returns, keyed by the dotted decode path matching the keys
+// built by Decode, the registeredType of every field whose type was taught to the package via
+// Register. A registered type is treated as an opaque leaf, regardless of its own Kind, so its
+// fields (if any) are never recursed into.
+func registeredPaths(rType reflect.Type, path []string) map[string]registeredType {
+	paths := make(map[string]registeredType)
+	walkRegisteredPaths(rType, path, paths)
+	return paths
+}
+
+func walkRegisteredPaths(rType reflect.Type, path []string, paths map[string]registeredType) {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if label, ok := field.Tag.Lookup(parser.TagLabel); ok && label != "" {
+			name = label
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = append(append([]string{}, path...), strings.ToLower(name))
+		}
+
+		if rt, ok := registry[field.Type]; ok {
+			paths[strings.Join(fieldPath, ".")] = rt
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			walkRegisteredPaths(field.Type, fieldPath, paths)
+		}
+	}
+}
+
+// fieldByPath returns the settable reflect.Value reached by descending from rValue through
+// segments, matching each segment against a field's label tag (or lower-cased name), the same way
+// registeredPaths built the path in the first place. Anonymous (embedded) fields are transparent.
+func fieldByPath(rValue reflect.Value, segments []string) (reflect.Value, bool) {
+	for rValue.Kind() == reflect.Ptr {
+		if rValue.IsNil() {
+			return reflect.Value{}, false
+		}
+		rValue = rValue.Elem()
+	}
+
+	if len(segments) == 0 {
+		return rValue, true
+	}
+
+	if rValue.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	rType := rValue.Type()
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous {
+			if found, ok := fieldByPath(rValue.Field(i), segments); ok {
+				return found, true
+			}
+			continue
+		}
+
+		name := field.Name
+		if label, ok := field.Tag.Lookup(parser.TagLabel); ok && label != "" {
+			name = label
+		}
+
+		if strings.ToLower(name) != segments[0] {
+			continue
+		}
+
+		return fieldByPath(rValue.Field(i), segments[1:])
+	}
+
+	return reflect.Value{}, false
+}
+
+// popRegisteredVars removes, from vars, every entry whose path was taught to the package via
+// Register, returning their raw values keyed by the same path so Decode can parse and apply them
+// itself once parser.Decode has populated the rest of element.
+func popRegisteredVars(vars map[string]string, regPaths map[string]registeredType) map[string]string {
+	raw := make(map[string]string, len(regPaths))
+
+	for path := range regPaths {
+		if v, ok := vars[path]; ok {
+			raw[path] = v
+			delete(vars, path)
+		}
+	}
+
+	return raw
+}
+
+// applyRegisteredVars parses each entry of raw with its registered type's parse function and sets
+// the corresponding field of element, once parser.Decode has already populated the rest of it.
+func applyRegisteredVars(element interface{}, regPaths map[string]registeredType, raw map[string]string) error {
+	rValue := reflect.ValueOf(element)
+
+	for path, value := range raw {
+		rt := regPaths[path]
+
+		parsed, err := rt.parse(value)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		field, ok := fieldByPath(rValue, strings.Split(path, ".")[1:])
+		if !ok {
+			continue
+		}
+
+		field.Set(reflect.ValueOf(parsed))
+	}
+
+	return nil
+}
+
+// overrideRegisteredDefaults replaces the Default of every Flat produced for a registered-type
+// field with the result of that type's encode function applied to the field's actual value in
+// element, since the built-in kind-based encoding in parser.EncodeToFlat has no notion of it.
+func overrideRegisteredDefaults(flats []parser.Flat, element interface{}, regPaths map[string]registeredType) []parser.Flat {
+	if len(regPaths) == 0 {
+		return flats
+	}
+
+	rValue := reflect.ValueOf(element)
+
+	names := make(map[string]registeredType, len(regPaths))
+	namePaths := make(map[string]string, len(regPaths))
+	for path, rt := range regPaths {
+		name := strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		names[name] = rt
+		namePaths[name] = path
+	}
+
+	for i, flat := range flats {
+		rt, ok := names[flat.Name]
+		if !ok {
+			continue
+		}
+
+		path := namePaths[flat.Name]
+
+		field, ok := fieldByPath(rValue, strings.Split(path, ".")[1:])
+		if !ok {
+			continue
+		}
+
+		flats[i].Default = rt.encode(field.Interface())
+	}
+
+	return flats
+}
+
+// resolveValue resolves a raw env var value, reading it from disk when it carries the file://
+// prefix. Values without the prefix are returned unchanged.
+func resolveValue(value string) (string, error) {
+	if !strings.HasPrefix(value, filePrefix) {
+		return value, nil
+	}
+
+	path := strings.TrimPrefix(value, filePrefix)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func init() {
+	Register(
+		func(value string) (net.IP, error) {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %q", value)
+			}
+			return ip, nil
+		},
+		func(ip net.IP) string {
+			return ip.String()
+		},
+	)
+}
+
+type registryConfig struct {
+	Bind net.IP
+}
+
+func TestRegister_RoundTrip(t *testing.T) {
+	var cfg registryConfig
+
+	err := Decode([]string{"TRAEFIK_BIND=127.0.0.1"}, []string{DefaultNamePrefix}, &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.Bind.Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("got %v, want 127.0.0.1", cfg.Bind)
+	}
+
+	flats, err := Encode(DefaultNamePrefix, &registryConfig{Bind: net.ParseIP("10.0.0.1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got string
+	for _, flat := range flats {
+		if flat.Name == "TRAEFIK_BIND" {
+			got = flat.Default
+		}
+	}
+
+	if got != "10.0.0.1" {
+		t.Fatalf("got %q, want %q", got, "10.0.0.1")
+	}
+}
+
+func TestRegister_DecodeInvalidValue(t *testing.T) {
+	var cfg registryConfig
+
+	err := Decode([]string{"TRAEFIK_BIND=not-an-ip"}, []string{DefaultNamePrefix}, &cfg)
+	if err == nil {
+		t.Fatal("expected an error for an invalid IP address")
+	}
+}
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// TagDescription is the struct tag read by the underlying parser metadata walk to document a
+// field, surfaced here as the description column of Usage/Flats.
+const TagDescription = "description"
+
+// Flat describes a single environment variable derivable from a Configuration, as reported by Flats.
+type Flat struct {
+	Name        string
+	Type        string
+	Default     string
+	Description string
+}
+
+// Usage writes an aligned table of every environment variable derivable from element to w: its
+// name, type, default value, and description. This mirrors envconfig-style self-documenting CLI
+// help, so binaries built on top of paerser can emit a `--help-env` output without hand-maintaining docs.
+func Usage(prefix string, element interface{}, w io.Writer) error {
+	flats, err := Flats(prefix, element)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tDESCRIPTION"); err != nil {
+		return err
+	}
+
+	for _, flat := range flats {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", flat.Name, flat.Type, flat.Default, flat.Description); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// Flats returns the rows Usage would print, letting callers build their own output format
+// (JSON, Markdown, etc.). It reuses the EncodeToFlat pipeline driven by Encode, which already
+// augments every node with metadata (kind, description) during AddMetadata, so the type and
+// description columns come straight from that pipeline rather than a second, divergent reflection
+// pass that could disagree with it on naming (e.g. for slice-as-struct entries).
+func Flats(prefix string, element interface{}) ([]Flat, error) {
+	encoded, err := Encode(prefix, element)
+	if err != nil {
+		return nil, err
+	}
+
+	flats := make([]Flat, 0, len(encoded))
+	for _, f := range encoded {
+		flats = append(flats, Flat{
+			Name:        f.Name,
+			Type:        f.Kind,
+			Default:     f.Default,
+			Description: f.Description,
+		})
+	}
+
+	return flats, nil
+}
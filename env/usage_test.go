@@ -0,0 +1,48 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+type usageConfig struct {
+	Level string `description:"the log level"`
+}
+
+func TestFlats(t *testing.T) {
+	flats, err := Flats(DefaultNamePrefix, &usageConfig{Level: "INFO"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got *Flat
+	for i, flat := range flats {
+		if flat.Name == "TRAEFIK_LEVEL" {
+			got = &flats[i]
+		}
+	}
+
+	if got == nil {
+		t.Fatalf("got no TRAEFIK_LEVEL entry in %#v", flats)
+	}
+
+	if got.Default != "INFO" {
+		t.Errorf("got Default %q, want %q", got.Default, "INFO")
+	}
+	if got.Description != "the log level" {
+		t.Errorf("got Description %q, want %q", got.Description, "the log level")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var buf strings.Builder
+
+	if err := Usage(DefaultNamePrefix, &usageConfig{Level: "INFO"}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "TRAEFIK_LEVEL") || !strings.Contains(out, "INFO") {
+		t.Fatalf("got %q, want it to mention TRAEFIK_LEVEL and INFO", out)
+	}
+}